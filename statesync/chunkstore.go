@@ -0,0 +1,141 @@
+package statesync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestFileName is the name of the on-disk manifest tracking progress of an in-progress
+// snapshot restoration. Its presence lets a restarted node resume a restore instead of
+// re-downloading every chunk from scratch.
+const manifestFileName = "statesync-manifest.json"
+
+// chunkManifest is the persisted record of an in-progress snapshot restoration: the snapshot
+// being restored, plus a bitmap of which chunk indices have been received, applied, or rejected.
+type chunkManifest struct {
+	Height         int64  `json:"height"`
+	Format         uint32 `json:"format"`
+	Hash           []byte `json:"hash"`
+	Chunks         uint32 `json:"chunks"`
+	TrustedAppHash []byte `json:"trusted_app_hash"`
+	Received       []bool `json:"received"`
+	Applied        []bool `json:"applied"`
+	Rejected       []bool `json:"rejected"`
+}
+
+// forSnapshot reports whether the manifest still matches the given snapshot and trusted app
+// hash, i.e. whether it's safe to resume from rather than starting over.
+func (m *chunkManifest) forSnapshot(snapshot *snapshot, trustedAppHash []byte) bool {
+	return m.Height == snapshot.Height &&
+		m.Format == snapshot.Format &&
+		bytesEqual(m.Hash, snapshot.Hash) &&
+		bytesEqual(m.TrustedAppHash, trustedAppHash)
+}
+
+// missingChunks returns the indices that still need to be fetched and applied.
+func (m *chunkManifest) missingChunks() []uint32 {
+	missing := make([]uint32, 0, m.Chunks)
+	for i := uint32(0); i < m.Chunks; i++ {
+		if !m.Applied[i] {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// markForRefetch clears the received/applied bits for index so applyChunks will re-fetch and
+// re-apply it, e.g. after the app rejects it via RefetchChunks. index is bounds-checked since it
+// comes straight from the ABCI app's response; a misbehaving app returning an out-of-range index
+// is ignored rather than panicking the node.
+func (m *chunkManifest) markForRefetch(index uint32) {
+	if index >= m.Chunks {
+		return
+	}
+	m.Applied[index] = false
+	m.Received[index] = false
+}
+
+// countTrue returns how many entries of bs are true, e.g. to report how many chunks of a
+// resumed manifest were already received or applied.
+func countTrue(bs []bool) int {
+	n := 0
+	for _, b := range bs {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ChunkStore persists the manifest of an in-progress snapshot restoration to disk, so a node
+// killed mid-restore can resume rather than re-download everything.
+type ChunkStore interface {
+	// Load returns the persisted manifest, or (nil, nil) if none exists.
+	Load() (*chunkManifest, error)
+	// Save atomically persists the manifest, overwriting any previous one.
+	Save(manifest *chunkManifest) error
+	// Delete removes the persisted manifest, e.g. once a restore completes.
+	Delete() error
+}
+
+// fileChunkStore is a ChunkStore backed by a single JSON manifest file alongside the chunk files
+// in tempDir. Saves are written to a temp file and renamed into place so a crash mid-write can't
+// leave behind a corrupt manifest.
+type fileChunkStore struct {
+	path string
+}
+
+// newFileChunkStore creates a ChunkStore that persists its manifest under tempDir.
+func newFileChunkStore(tempDir string) *fileChunkStore {
+	return &fileChunkStore{path: filepath.Join(tempDir, manifestFileName)}
+}
+
+func (f *fileChunkStore) Load() (*chunkManifest, error) {
+	bz, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read chunk manifest: %w", err)
+	}
+	manifest := &chunkManifest{}
+	if err := json.Unmarshal(bz, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func (f *fileChunkStore) Save(manifest *chunkManifest) error {
+	bz, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunk manifest: %w", err)
+	}
+	tmpPath := f.path + ".tmp"
+	if err := os.WriteFile(tmpPath, bz, 0o644); err != nil {
+		return fmt.Errorf("failed to write chunk manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		return fmt.Errorf("failed to persist chunk manifest: %w", err)
+	}
+	return nil
+}
+
+func (f *fileChunkStore) Delete() error {
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove chunk manifest: %w", err)
+	}
+	return nil
+}
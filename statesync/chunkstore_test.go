@@ -0,0 +1,74 @@
+package statesync
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileChunkStoreSaveLoadRoundTrip(t *testing.T) {
+	store := newFileChunkStore(t.TempDir())
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Nil(t, loaded, "no manifest saved yet")
+
+	manifest := &chunkManifest{
+		Height:         100,
+		Format:         1,
+		Hash:           []byte("hash"),
+		Chunks:         3,
+		TrustedAppHash: []byte("apphash"),
+		Received:       []bool{true, true, false},
+		Applied:        []bool{true, false, false},
+		Rejected:       []bool{false, false, false},
+	}
+	require.NoError(t, store.Save(manifest))
+
+	loaded, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, manifest, loaded)
+
+	require.NoError(t, store.Delete())
+	loaded, err = store.Load()
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+}
+
+func TestFileChunkStoreSaveIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	store := newFileChunkStore(dir)
+
+	manifest := &chunkManifest{Height: 1, Format: 1, Chunks: 1, Received: []bool{true}, Applied: []bool{true}, Rejected: []bool{false}}
+	require.NoError(t, store.Save(manifest))
+
+	// Save should go through a .tmp file that's renamed into place, never leaving the tmp file
+	// behind on success.
+	tmpPath := filepath.Join(dir, manifestFileName+".tmp")
+	require.NoFileExists(t, tmpPath)
+}
+
+func TestChunkManifestMissingChunks(t *testing.T) {
+	manifest := &chunkManifest{
+		Chunks:  4,
+		Applied: []bool{true, false, true, false},
+	}
+	require.Equal(t, []uint32{1, 3}, manifest.missingChunks())
+}
+
+func TestChunkManifestForSnapshot(t *testing.T) {
+	snap := &snapshot{Height: 10, Format: 1, Hash: []byte("h")}
+	manifest := &chunkManifest{Height: 10, Format: 1, Hash: []byte("h"), TrustedAppHash: []byte("app")}
+
+	require.True(t, manifest.forSnapshot(snap, []byte("app")))
+	require.False(t, manifest.forSnapshot(snap, []byte("other")))
+
+	other := &snapshot{Height: 11, Format: 1, Hash: []byte("h")}
+	require.False(t, manifest.forSnapshot(other, []byte("app")))
+}
+
+func TestCountTrue(t *testing.T) {
+	require.Equal(t, 2, countTrue([]bool{true, false, true, false}))
+	require.Equal(t, 0, countTrue(nil))
+}
@@ -0,0 +1,42 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecsRoundTrip(t *testing.T) {
+	data := []byte("a snapshot chunk's worth of IAVL node bytes, repeated, repeated, repeated")
+
+	for name, codec := range registeredCodecs {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := codec.Encode(data)
+			require.NoError(t, err)
+
+			decoded, err := codec.Decode(encoded)
+			require.NoError(t, err)
+			require.Equal(t, data, decoded)
+		})
+	}
+}
+
+func TestCodecByName(t *testing.T) {
+	c, ok := codecByName("gzip")
+	require.True(t, ok)
+	require.Equal(t, "gzip", c.Name())
+
+	c, ok = codecByName("")
+	require.True(t, ok, "an empty codec name should fall back to none for old peers")
+	require.Equal(t, "none", c.Name())
+
+	_, ok = codecByName("lz4")
+	require.False(t, ok)
+}
+
+func TestNegotiateCodec(t *testing.T) {
+	require.Equal(t, "zstd", negotiateCodec([]string{"zstd", "gzip", "none"}, []string{"gzip", "zstd"}))
+	require.Equal(t, "gzip", negotiateCodec([]string{"zstd", "gzip", "none"}, []string{"gzip", "none"}))
+	require.Equal(t, "none", negotiateCodec([]string{"zstd", "gzip", "none"}, nil), "no advertised codecs falls back to none")
+	require.Equal(t, "none", negotiateCodec([]string{"zstd"}, []string{"gzip"}), "no overlap falls back to none")
+}
@@ -0,0 +1,195 @@
+package statesync
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this package.
+	MetricsSubsystem = "statesync"
+)
+
+// Metrics contains metrics exposed by this package. It replaces the ad-hoc
+// logger.Info("... latency is: %d") calls the syncer used to instrument itself with, so operators
+// can build Grafana dashboards for restore progress instead of grepping logs.
+type Metrics struct {
+	// SnapshotsDiscovered counts the total number of distinct snapshots discovered from peers.
+	SnapshotsDiscovered metrics.Counter
+
+	// SnapshotHeight is the height of the snapshot currently being restored.
+	SnapshotHeight metrics.Gauge
+	// SnapshotFormat is the format of the snapshot currently being restored.
+	SnapshotFormat metrics.Gauge
+
+	// ChunksTotal is the total number of chunks in the snapshot currently being restored.
+	ChunksTotal metrics.Gauge
+	// ChunksReceived is the number of chunks received so far for the current snapshot.
+	ChunksReceived metrics.Gauge
+	// ChunksApplied is the number of chunks applied so far for the current snapshot.
+	ChunksApplied metrics.Gauge
+	// ChunksRejected is the number of chunks the app has asked us to refetch.
+	ChunksRejected metrics.Gauge
+
+	// OfferSnapshotDuration tracks how long OfferSnapshot ABCI calls take.
+	OfferSnapshotDuration metrics.Histogram
+	// ApplyChunkDuration tracks how long ApplySnapshotChunk ABCI calls take.
+	ApplyChunkDuration metrics.Histogram
+	// FetchChunkDuration tracks how long it takes to receive a chunk after requesting it.
+	FetchChunkDuration metrics.Histogram
+	// VerifyAppDuration tracks how long app hash/state/commit verification against the light
+	// client takes.
+	VerifyAppDuration metrics.Histogram
+
+	// PeerChunksReceived counts chunks successfully received per peer.
+	PeerChunksReceived metrics.Counter
+	// PeerChunkFailures counts chunk requests per peer that timed out or failed verification.
+	PeerChunkFailures metrics.Counter
+
+	// SyncDuration tracks the total wall-clock time of a state sync, from SyncAny() to success
+	// or abort.
+	SyncDuration metrics.Histogram
+}
+
+// PrometheusMetrics returns Metrics built using Prometheus client metrics, with optional
+// label/value pairs applied to every metric (e.g. "chain_id", chainID).
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	peerLabels := append(append([]string{}, labels...), "peer_id")
+
+	return &Metrics{
+		SnapshotsDiscovered: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "snapshots_discovered_total",
+			Help:      "Number of distinct snapshots discovered from peers.",
+		}, labels).With(labelsAndValues...),
+
+		SnapshotHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "snapshot_height",
+			Help:      "Height of the snapshot currently being restored.",
+		}, labels).With(labelsAndValues...),
+
+		SnapshotFormat: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "snapshot_format",
+			Help:      "Format of the snapshot currently being restored.",
+		}, labels).With(labelsAndValues...),
+
+		ChunksTotal: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "chunks_total",
+			Help:      "Total number of chunks in the snapshot currently being restored.",
+		}, labels).With(labelsAndValues...),
+
+		ChunksReceived: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "chunks_received",
+			Help:      "Number of chunks received so far for the current snapshot.",
+		}, labels).With(labelsAndValues...),
+
+		ChunksApplied: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "chunks_applied",
+			Help:      "Number of chunks applied so far for the current snapshot.",
+		}, labels).With(labelsAndValues...),
+
+		ChunksRejected: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "chunks_rejected",
+			Help:      "Number of chunks the app has asked us to refetch for the current snapshot.",
+		}, labels).With(labelsAndValues...),
+
+		OfferSnapshotDuration: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "offer_snapshot_seconds",
+			Help:      "Time taken by OfferSnapshot ABCI calls, in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, labels).With(labelsAndValues...),
+
+		ApplyChunkDuration: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "apply_chunk_seconds",
+			Help:      "Time taken by ApplySnapshotChunk ABCI calls, in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, labels).With(labelsAndValues...),
+
+		FetchChunkDuration: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "fetch_chunk_seconds",
+			Help:      "Time taken to receive a chunk after requesting it, in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, labels).With(labelsAndValues...),
+
+		VerifyAppDuration: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "verify_app_seconds",
+			Help:      "Time taken to fetch and verify app hash/state/commit, in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, labels).With(labelsAndValues...),
+
+		PeerChunksReceived: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_chunks_received_total",
+			Help:      "Number of chunks successfully received, by peer.",
+		}, peerLabels).With(labelsAndValues...),
+
+		PeerChunkFailures: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_chunk_failures_total",
+			Help:      "Number of chunk requests that timed out or failed verification, by peer.",
+		}, peerLabels).With(labelsAndValues...),
+
+		SyncDuration: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "sync_total_seconds",
+			Help:      "Total time taken by a state sync, from start to success or abort, in seconds.",
+			Buckets:   []float64{1, 10, 30, 60, 300, 600, 1800, 3600, 7200},
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns Metrics that discard all observations, for use where Prometheus isn't
+// configured.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		SnapshotsDiscovered: discard.NewCounter(),
+
+		SnapshotHeight: discard.NewGauge(),
+		SnapshotFormat: discard.NewGauge(),
+
+		ChunksTotal:    discard.NewGauge(),
+		ChunksReceived: discard.NewGauge(),
+		ChunksApplied:  discard.NewGauge(),
+		ChunksRejected: discard.NewGauge(),
+
+		OfferSnapshotDuration: discard.NewHistogram(),
+		ApplyChunkDuration:    discard.NewHistogram(),
+		FetchChunkDuration:    discard.NewHistogram(),
+		VerifyAppDuration:     discard.NewHistogram(),
+
+		PeerChunksReceived: discard.NewCounter(),
+		PeerChunkFailures:  discard.NewCounter(),
+
+		SyncDuration: discard.NewHistogram(),
+	}
+}
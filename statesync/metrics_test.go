@@ -0,0 +1,39 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNopMetricsFieldsAreUsable(t *testing.T) {
+	m := NopMetrics()
+	require.NotNil(t, m)
+
+	require.NotPanics(t, func() {
+		m.SnapshotsDiscovered.Add(1)
+		m.SnapshotHeight.Set(100)
+		m.SnapshotFormat.Set(1)
+		m.ChunksTotal.Set(10)
+		m.ChunksReceived.Set(5)
+		m.ChunksApplied.Set(5)
+		m.ChunksRejected.Add(1)
+		m.OfferSnapshotDuration.Observe(0.1)
+		m.ApplyChunkDuration.Observe(0.1)
+		m.FetchChunkDuration.Observe(0.1)
+		m.VerifyAppDuration.Observe(0.1)
+		m.PeerChunksReceived.With("peer_id", "p1").Add(1)
+		m.PeerChunkFailures.With("peer_id", "p1").Add(1)
+		m.SyncDuration.Observe(1)
+	})
+}
+
+func TestPrometheusMetricsWithLabels(t *testing.T) {
+	m := PrometheusMetrics("tm_test_statesync_metrics", "chain_id", "test-chain")
+	require.NotNil(t, m)
+
+	require.NotPanics(t, func() {
+		m.SnapshotsDiscovered.Add(1)
+		m.PeerChunksReceived.With("peer_id", "p1").Add(1)
+	})
+}
@@ -0,0 +1,139 @@
+package statesync
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/libs/sync"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// defaultRTTAlpha is the EWMA smoothing factor used when StateSyncConfig.RTTAlpha isn't set.
+const defaultRTTAlpha = 0.3
+
+// peerScore tracks a single peer's recent chunk-fetching performance: an EWMA of round-trip
+// time (and its variance, for an adaptive timeout), an EWMA of throughput, and an EWMA failure
+// rate. It's deliberately softer than snapshots.RejectPeer - a bad score just drops a peer's
+// rank, it doesn't blacklist it, since RejectPeer is reserved for outright malicious behavior.
+type peerScore struct {
+	rtt         time.Duration
+	rttVariance float64
+	bytesPerSec float64
+	failures    float64
+	samples     int
+}
+
+func (s *peerScore) recordSuccess(alpha float64, rtt time.Duration, bytes int) {
+	if s.samples == 0 {
+		s.rtt = rtt
+	} else {
+		delta := float64(rtt - s.rtt)
+		s.rtt += time.Duration(alpha * delta)
+		s.rttVariance = (1 - alpha) * (s.rttVariance + alpha*delta*delta)
+	}
+	if rtt > 0 {
+		bps := float64(bytes) / rtt.Seconds()
+		s.bytesPerSec = alpha*bps + (1-alpha)*s.bytesPerSec
+	}
+	s.failures *= 1 - alpha
+	s.samples++
+}
+
+func (s *peerScore) recordFailure(alpha float64) {
+	s.failures = alpha + (1-alpha)*s.failures
+	s.samples++
+}
+
+// value combines rtt, throughput and failure rate into a single ranking value, higher is
+// better. Peers with no samples yet score 0 so they're tried before known-bad peers but after
+// peers with a positive track record.
+func (s *peerScore) value() float64 {
+	if s.samples == 0 {
+		return 0
+	}
+	rttPenalty := s.rtt.Seconds()
+	return s.bytesPerSec/(1+rttPenalty) - 1000*s.failures
+}
+
+func (s *peerScore) stddev() time.Duration {
+	if s.rttVariance <= 0 {
+		return 0
+	}
+	return time.Duration(math.Sqrt(s.rttVariance))
+}
+
+// peerScoreTracker maintains a peerScore per peer for the duration of a restore, and turns that
+// into a ranked peer list and adaptive per-peer request timeouts for fetchChunks.
+type peerScoreTracker struct {
+	alpha float64
+
+	mtx    tmsync.Mutex
+	scores map[p2p.ID]*peerScore
+}
+
+func newPeerScoreTracker(alpha float64) *peerScoreTracker {
+	if alpha <= 0 || alpha > 1 {
+		alpha = defaultRTTAlpha
+	}
+	return &peerScoreTracker{
+		alpha:  alpha,
+		scores: make(map[p2p.ID]*peerScore),
+	}
+}
+
+func (t *peerScoreTracker) get(peer p2p.ID) *peerScore {
+	s, ok := t.scores[peer]
+	if !ok {
+		s = &peerScore{}
+		t.scores[peer] = s
+	}
+	return s
+}
+
+// RecordSuccess folds a successful chunk fetch's RTT and payload size into peer's score.
+func (t *peerScoreTracker) RecordSuccess(peer p2p.ID, rtt time.Duration, bytes int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.get(peer).recordSuccess(t.alpha, rtt, bytes)
+}
+
+// RecordFailure decays peer's score after a request timeout or a chunk that failed hash
+// verification.
+func (t *peerScoreTracker) RecordFailure(peer p2p.ID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.get(peer).recordFailure(t.alpha)
+}
+
+// Ranked returns peers sorted by descending score, highest (best) first.
+func (t *peerScoreTracker) Ranked(peers []p2p.Peer) []p2p.Peer {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	ranked := make([]p2p.Peer, len(peers))
+	copy(ranked, peers)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return t.get(ranked[i].ID()).value() > t.get(ranked[j].ID()).value()
+	})
+	return ranked
+}
+
+// AdaptiveTimeout returns the request timeout for peer: mean RTT plus k standard deviations,
+// clamped to [min, max]. Peers with no samples yet get max, since we don't know their RTT.
+func (t *peerScoreTracker) AdaptiveTimeout(peer p2p.ID, k float64, min, max time.Duration) time.Duration {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	s := t.get(peer)
+	if s.samples == 0 {
+		return max
+	}
+	timeout := s.rtt + time.Duration(k*float64(s.stddev()))
+	switch {
+	case timeout < min:
+		return min
+	case timeout > max:
+		return max
+	default:
+		return timeout
+	}
+}
@@ -0,0 +1,54 @@
+package statesync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerScoreTrackerRecordSuccessImprovesValue(t *testing.T) {
+	tracker := newPeerScoreTracker(0.5)
+
+	unseen := tracker.get("unseen")
+	require.Equal(t, float64(0), unseen.value(), "a peer with no samples scores 0")
+
+	tracker.RecordSuccess("fast", 10*time.Millisecond, 1<<20)
+	fast := tracker.get("fast")
+	require.Greater(t, fast.value(), float64(0))
+
+	tracker.RecordSuccess("slow", time.Second, 1<<10)
+	slow := tracker.get("slow")
+	require.Greater(t, fast.value(), slow.value(), "a faster, higher-throughput peer should score higher")
+}
+
+func TestPeerScoreTrackerRecordFailureDecaysValue(t *testing.T) {
+	tracker := newPeerScoreTracker(0.5)
+	tracker.RecordSuccess("peer", 10*time.Millisecond, 1<<20)
+	before := tracker.get("peer").value()
+
+	tracker.RecordFailure("peer")
+	after := tracker.get("peer").value()
+
+	require.Less(t, after, before, "a recorded failure should lower the peer's score")
+}
+
+func TestPeerScoreTrackerAdaptiveTimeoutClamps(t *testing.T) {
+	tracker := newPeerScoreTracker(0.5)
+
+	require.Equal(t, 4*time.Second, tracker.AdaptiveTimeout("unseen", 2, time.Second, 4*time.Second),
+		"a peer with no samples yet should get the max timeout")
+
+	tracker.RecordSuccess("steady", 500*time.Millisecond, 1<<20)
+	timeout := tracker.AdaptiveTimeout("steady", 2, time.Second, 4*time.Second)
+	require.GreaterOrEqual(t, timeout, time.Second)
+	require.LessOrEqual(t, timeout, 4*time.Second)
+}
+
+func TestPeerScoreDefaultAlpha(t *testing.T) {
+	tracker := newPeerScoreTracker(0)
+	require.Equal(t, defaultRTTAlpha, tracker.alpha)
+
+	tracker = newPeerScoreTracker(1.5)
+	require.Equal(t, defaultRTTAlpha, tracker.alpha)
+}
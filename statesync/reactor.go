@@ -0,0 +1,312 @@
+package statesync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	tmsync "github.com/tendermint/tendermint/libs/sync"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/conn"
+	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// SnapshotChannel exchanges snapshot metadata with peers.
+	SnapshotChannel = byte(0x60)
+	// ChunkChannel exchanges snapshot chunks with peers.
+	ChunkChannel = byte(0x61)
+
+	snapshotMsgSize = int(4e6)
+	chunkMsgSize    = int(16e6)
+)
+
+// Reactor handles state sync for a node: it drives restoring a snapshot for the local node via
+// Sync(), and serves snapshots and chunks to peers that are restoring one of their own.
+type Reactor struct {
+	p2p.BaseReactor
+
+	cfg       config.StateSyncConfig
+	conn      proxy.AppConnSnapshot
+	connQuery proxy.AppConnQuery
+	tempDir   string
+	metrics   *Metrics
+
+	// ctx is this reactor's lifecycle context, cancelled in OnStop. It's the parent of every
+	// syncer created by Sync(), so stopping the reactor mid-restore promptly unblocks the
+	// syncer's fetch/apply goroutines instead of deadlocking OnStop against them (see newSyncer).
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx    tmsync.RWMutex
+	syncer *syncer
+}
+
+// NewReactor creates a new state sync reactor. metrics may be nil, in which case metrics are
+// disabled (see NopMetrics); callers that want Grafana-visible restore progress should pass
+// PrometheusMetrics(namespace, ...) instead, with the node's instrumentation namespace, the same
+// way other reactors are wired.
+func NewReactor(
+	cfg config.StateSyncConfig,
+	logger log.Logger,
+	conn proxy.AppConnSnapshot,
+	connQuery proxy.AppConnQuery,
+	tempDir string,
+	metrics *Metrics,
+) *Reactor {
+	if metrics == nil {
+		metrics = NopMetrics()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Reactor{
+		cfg:       cfg,
+		conn:      conn,
+		connQuery: connQuery,
+		tempDir:   tempDir,
+		metrics:   metrics,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	r.BaseReactor = *p2p.NewBaseReactor("StateSync", r)
+	r.SetLogger(logger)
+	return r
+}
+
+// OnStart implements p2p.Reactor.
+func (r *Reactor) OnStart() error {
+	return nil
+}
+
+// OnStop implements p2p.Reactor. It cancels the reactor's lifecycle context so any syncer
+// created by a Sync() call currently in progress stops blocking on its RWMutex and returns
+// errSyncerStopped instead of deadlocking this call.
+func (r *Reactor) OnStop() {
+	r.cancel()
+}
+
+// GetChannels implements p2p.Reactor.
+func (r *Reactor) GetChannels() []*conn.ChannelDescriptor {
+	return []*conn.ChannelDescriptor{
+		{
+			ID:                  SnapshotChannel,
+			Priority:            5,
+			SendQueueCapacity:   10,
+			RecvMessageCapacity: snapshotMsgSize,
+		},
+		{
+			ID:                  ChunkChannel,
+			Priority:            3,
+			SendQueueCapacity:   4,
+			RecvMessageCapacity: chunkMsgSize,
+		},
+	}
+}
+
+// AddPeer implements p2p.Reactor.
+func (r *Reactor) AddPeer(peer p2p.Peer) {
+	if s := r.activeSyncer(); s != nil {
+		s.AddPeer(peer)
+	}
+}
+
+// RemovePeer implements p2p.Reactor.
+func (r *Reactor) RemovePeer(peer p2p.Peer, reason interface{}) {
+	if s := r.activeSyncer(); s != nil {
+		s.RemovePeer(peer)
+	}
+}
+
+// activeSyncer returns the syncer for the restore currently in progress, or nil if none is.
+func (r *Reactor) activeSyncer() *syncer {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.syncer
+}
+
+// Sync drives a state sync restore to completion, against a syncer bound to the reactor's
+// lifecycle context (see newSyncer) so OnStop can cancel it mid-restore.
+func (r *Reactor) Sync(stateProvider StateProvider, discoveryTime time.Duration) (sm.State, *types.Commit, error) {
+	s := newSyncer(r.ctx, r.cfg, r.Logger, r.conn, r.connQuery, stateProvider, r.tempDir, r.metrics)
+	r.mtx.Lock()
+	r.syncer = s
+	r.mtx.Unlock()
+	defer func() {
+		r.mtx.Lock()
+		r.syncer = nil
+		r.mtx.Unlock()
+	}()
+
+	return s.SyncAny(discoveryTime, func() {})
+}
+
+// Receive implements p2p.Reactor, routing incoming snapshot/chunk messages to the active syncer
+// (if any) or, for requests from peers restoring their own snapshot, to the local ABCI app.
+func (r *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
+	msg, err := decodeMsg(msgBytes)
+	if err != nil {
+		r.Logger.Error("Error decoding message", "peer", peer.ID(), "err", err)
+		return
+	}
+
+	switch msg := msg.(type) {
+	case *ssproto.SnapshotsRequest:
+		r.respondToSnapshotsRequest(peer)
+
+	case *ssproto.SnapshotsResponse:
+		s := r.activeSyncer()
+		if s == nil {
+			return
+		}
+		s.SetPeerCodecs(peer.ID(), msg.SupportedCodecs)
+		if _, err := s.AddSnapshot(peer, &snapshot{
+			Height:        int64(msg.Height),
+			Format:        msg.Format,
+			Hash:          msg.Hash,
+			Chunks:        msg.Chunks,
+			Metadata:      msg.Metadata,
+			ParallelApply: msg.ParallelApply,
+		}); err != nil {
+			r.Logger.Error("Failed to add snapshot", "peer", peer.ID(), "err", err)
+		}
+
+	case *ssproto.ChunkRequest:
+		r.respondToChunkRequest(peer, msg)
+
+	case *ssproto.ChunkResponse:
+		s := r.activeSyncer()
+		if s == nil {
+			return
+		}
+		if _, err := s.AddChunk(&chunk{
+			Height: int64(msg.Height),
+			Format: msg.Format,
+			Index:  msg.Index,
+			Chunk:  msg.Chunk,
+			Sender: peer.ID(),
+			Codec:  msg.Codec,
+		}); err != nil {
+			r.Logger.Error("Failed to add chunk", "peer", peer.ID(), "height", msg.Height,
+				"format", msg.Format, "chunk", msg.Index, "err", err)
+		}
+
+	default:
+		r.Logger.Error("Received unknown message", "peer", peer.ID(), "msg", fmt.Sprintf("%T", msg))
+	}
+}
+
+func mustEncodeMsg(msg proto.Message) []byte {
+	pb := ssproto.Message{}
+	switch msg := msg.(type) {
+	case *ssproto.ChunkRequest:
+		pb.Sum = &ssproto.Message_ChunkRequest{ChunkRequest: msg}
+	case *ssproto.ChunkResponse:
+		pb.Sum = &ssproto.Message_ChunkResponse{ChunkResponse: msg}
+	case *ssproto.SnapshotsRequest:
+		pb.Sum = &ssproto.Message_SnapshotsRequest{SnapshotsRequest: msg}
+	case *ssproto.SnapshotsResponse:
+		pb.Sum = &ssproto.Message_SnapshotsResponse{SnapshotsResponse: msg}
+	default:
+		panic(fmt.Errorf("unknown message type %T", msg))
+	}
+	bz, err := proto.Marshal(&pb)
+	if err != nil {
+		panic(fmt.Errorf("unable to marshal %T: %w", msg, err))
+	}
+	return bz
+}
+
+func decodeMsg(bz []byte) (proto.Message, error) {
+	pb := &ssproto.Message{}
+	if err := proto.Unmarshal(bz, pb); err != nil {
+		return nil, err
+	}
+	switch msg := pb.Sum.(type) {
+	case *ssproto.Message_ChunkRequest:
+		return msg.ChunkRequest, nil
+	case *ssproto.Message_ChunkResponse:
+		return msg.ChunkResponse, nil
+	case *ssproto.Message_SnapshotsRequest:
+		return msg.SnapshotsRequest, nil
+	case *ssproto.Message_SnapshotsResponse:
+		return msg.SnapshotsResponse, nil
+	default:
+		return nil, fmt.Errorf("unknown message: %T", msg)
+	}
+}
+
+// respondToSnapshotsRequest replies to a peer's SnapshotsRequest with every snapshot the local
+// ABCI app currently offers, tagged with the chunk codecs we support so the peer can narrow its
+// ChunkRequests to the overlap (see codecsForPeer, which the requesting node feeds from this
+// response via the SetPeerCodecs call in Receive).
+func (r *Reactor) respondToSnapshotsRequest(peer p2p.Peer) {
+	resp, err := r.conn.ListSnapshotsSync(abci.RequestListSnapshots{})
+	if err != nil {
+		r.Logger.Error("Failed to list snapshots", "err", err)
+		return
+	}
+	for _, s := range resp.Snapshots {
+		peer.Send(SnapshotChannel, mustEncodeMsg(&ssproto.SnapshotsResponse{
+			Height:          s.Height,
+			Format:          s.Format,
+			Chunks:          s.Chunks,
+			Hash:            s.Hash,
+			Metadata:        s.Metadata,
+			SupportedCodecs: supportedCodecNames(),
+		}))
+	}
+}
+
+// respondToChunkRequest loads the requested chunk from the local ABCI app and encodes it with
+// whichever codec EncodeChunkForResponse negotiates from msg.Codecs. We serve chunks regardless of
+// whether we're also restoring a snapshot ourselves, so this doesn't depend on an active syncer -
+// EncodeChunkForResponse is a plain function over the package-level codec registry, not per-sync state.
+func (r *Reactor) respondToChunkRequest(peer p2p.Peer, msg *ssproto.ChunkRequest) {
+	resp, err := r.conn.LoadSnapshotChunkSync(abci.RequestLoadSnapshotChunk{
+		Height: msg.Height,
+		Format: msg.Format,
+		Chunk:  msg.Index,
+	})
+	if err != nil {
+		r.Logger.Error("Failed to load snapshot chunk", "height", msg.Height, "format", msg.Format,
+			"chunk", msg.Index, "err", err)
+		return
+	}
+
+	chunkBytes, codecName := resp.Chunk, "none"
+	if resp.Chunk != nil {
+		encoded, name, err := EncodeChunkForResponse(msg.Codecs, resp.Chunk)
+		if err != nil {
+			r.Logger.Error("Failed to encode snapshot chunk, sending uncompressed", "height", msg.Height,
+				"format", msg.Format, "chunk", msg.Index, "err", err)
+		} else {
+			chunkBytes, codecName = encoded, name
+		}
+	}
+
+	peer.Send(ChunkChannel, mustEncodeMsg(&ssproto.ChunkResponse{
+		Height:  msg.Height,
+		Format:  msg.Format,
+		Index:   msg.Index,
+		Chunk:   chunkBytes,
+		Codec:   codecName,
+		Missing: resp.Chunk == nil,
+	}))
+}
+
+// supportedCodecNames returns the names of every codec this node can decode, to advertise in our
+// SnapshotsResponse's supported_codecs field.
+func supportedCodecNames() []string {
+	names := make([]string, 0, len(registeredCodecs))
+	for name := range registeredCodecs {
+		names = append(names, name)
+	}
+	return names
+}
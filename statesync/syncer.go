@@ -46,62 +46,185 @@ var (
 	errTimeout = errors.New("timed out waiting for chunk")
 	// errNoSnapshots is returned by SyncAny() if no snapshots are found and discovery is disabled.
 	errNoSnapshots = errors.New("no suitable snapshots found")
+	// errSyncerStopped is returned by AddChunk/AddSnapshot once the syncer's context has been
+	// cancelled, e.g. by the reactor's OnStop, and by an in-progress SyncAny/Sync that reaches a
+	// discovery sleep, offerSnapshot or verifyApp after that; applyChunks returns the context's own
+	// error instead. Callers should treat it like any other fast-fail: don't retry, don't wait on
+	// the syncer any further.
+	errSyncerStopped = errors.New("syncer has stopped")
 )
 
+// chunkRequestKey identifies a single in-flight chunk request to a single peer, so fanning the
+// same chunk index out to several peers in parallel doesn't clobber each other's send timestamps.
+type chunkRequestKey struct {
+	index uint32
+	peer  p2p.ID
+}
+
 // syncer runs a state sync against an ABCI app. Use either SyncAny() to automatically attempt to
 // sync all snapshots in the pool (pausing to discover new ones), or Sync() to sync a specific
 // snapshot. Snapshots and chunks are fed via AddSnapshot() and AddChunk() as appropriate.
 type syncer struct {
-	logger        log.Logger
-	stateProvider StateProvider
-	conn          proxy.AppConnSnapshot
-	connQuery     proxy.AppConnQuery
-	snapshots     *snapshotPool
-	tempDir       string
-	chunkFetchers int32
-	retryTimeout  time.Duration
-
-	mtx    tmsync.RWMutex
-	chunks *chunkQueue
+	// ctx is the reactor-level lifecycle context, cancelled in the reactor's OnStop. It's the
+	// parent of every context this syncer derives for a Sync() call, so stopping the reactor
+	// promptly unblocks any in-progress fetch/apply goroutines instead of deadlocking against
+	// mtx, which AddChunk holds (read-locked) for the duration of the RPC it handles.
+	ctx               context.Context
+	logger            log.Logger
+	stateProvider     StateProvider
+	conn              proxy.AppConnSnapshot
+	connQuery         proxy.AppConnQuery
+	snapshots         *snapshotPool
+	tempDir           string
+	chunkFetchers     int32
+	chunkApplyWorkers int32
+	retryTimeout      time.Duration
+	chunkStore        ChunkStore
+	parallelRequests  int32
+	timeoutStdDevs    float64
+	peerScores        *peerScoreTracker
+	metrics           *Metrics
+	preferredCodecs   []string
+
+	mtx      tmsync.RWMutex
+	chunks   *chunkQueue
+	manifest *chunkManifest
+
+	// auxMtx guards manifest field updates, chunkSentAt and peerCodecs, which can all be written
+	// concurrently by AddChunk (called per-incoming-chunk, holding only mtx's read lock), by
+	// handleApplyResult, by fetchChunks (via requestChunkFrom), and by SetPeerCodecs.
+	auxMtx tmsync.Mutex
+	// chunkSentAt is keyed by (index, peer), not just index: fetchChunk fans a single chunk
+	// request out to multiple peers in parallel, so a shared per-index timestamp would get
+	// clobbered by whichever peer we last dispatched to, and AddChunk would then attribute the
+	// wrong peer's RTT to peerScores.RecordSuccess.
+	chunkSentAt map[chunkRequestKey]time.Time
+	peerCodecs  map[p2p.ID][]string
 }
 
-// newSyncer creates a new syncer.
+// newSyncer creates a new syncer. ctx is the reactor-level lifecycle context; it should be
+// cancelled from the reactor's OnStop so that a stopped reactor can't deadlock waiting on a
+// syncer that's still fetching or applying chunks.
 func newSyncer(
+	ctx context.Context,
 	cfg config.StateSyncConfig,
 	logger log.Logger,
 	conn proxy.AppConnSnapshot,
 	connQuery proxy.AppConnQuery,
 	stateProvider StateProvider,
 	tempDir string,
+	metrics *Metrics,
 ) *syncer {
+	if metrics == nil {
+		metrics = NopMetrics()
+	}
+
+	chunkApplyWorkers := cfg.ChunkApplyWorkers
+	if chunkApplyWorkers <= 0 {
+		chunkApplyWorkers = 1
+	}
+
+	parallelRequests := cfg.ParallelRequests
+	if parallelRequests <= 0 {
+		parallelRequests = 1
+	}
+
+	timeoutStdDevs := cfg.TimeoutStdDevs
+	if timeoutStdDevs <= 0 {
+		timeoutStdDevs = 2
+	}
+
+	preferredCodecs := cfg.PreferredCodecs
+	if len(preferredCodecs) == 0 {
+		preferredCodecs = []string{"zstd", "gzip", "none"}
+	}
 
 	return &syncer{
-		logger:        logger,
-		stateProvider: stateProvider,
-		conn:          conn,
-		connQuery:     connQuery,
-		snapshots:     newSnapshotPool(),
-		tempDir:       tempDir,
-		chunkFetchers: cfg.ChunkFetchers,
-		retryTimeout:  cfg.ChunkRequestTimeout,
+		ctx:               ctx,
+		logger:            logger,
+		stateProvider:     stateProvider,
+		conn:              conn,
+		connQuery:         connQuery,
+		snapshots:         newSnapshotPool(),
+		tempDir:           tempDir,
+		chunkFetchers:     cfg.ChunkFetchers,
+		chunkApplyWorkers: chunkApplyWorkers,
+		retryTimeout:      cfg.ChunkRequestTimeout,
+		chunkStore:        newFileChunkStore(tempDir),
+		parallelRequests:  parallelRequests,
+		timeoutStdDevs:    timeoutStdDevs,
+		peerScores:        newPeerScoreTracker(cfg.RTTAlpha),
+		metrics:           metrics,
+		preferredCodecs:   preferredCodecs,
+		chunkSentAt:       make(map[chunkRequestKey]time.Time),
+		peerCodecs:        make(map[p2p.ID][]string),
 	}
 }
 
 // AddChunk adds a chunk to the chunk queue, if any. It returns false if the chunk has already
 // been added to the queue, or an error if there's no sync in progress.
 func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
+	select {
+	case <-s.ctx.Done():
+		return false, errSyncerStopped
+	default:
+	}
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
 	if s.chunks == nil {
 		return false, errors.New("no state sync in progress")
 	}
+	codec, ok := codecByName(chunk.Codec)
+	if !ok {
+		s.logger.Error("Discarding chunk with unsupported codec", "height", chunk.Height,
+			"format", chunk.Format, "chunk", chunk.Index, "codec", chunk.Codec)
+		if chunk.Sender != "" {
+			s.peerScores.RecordFailure(chunk.Sender)
+		}
+		return false, fmt.Errorf("unsupported chunk codec %q", chunk.Codec)
+	}
+	decoded, err := codec.Decode(chunk.Chunk)
+	if err != nil {
+		s.logger.Error("Discarding chunk that failed to decode", "height", chunk.Height,
+			"format", chunk.Format, "chunk", chunk.Index, "codec", chunk.Codec, "err", err)
+		if chunk.Sender != "" {
+			s.peerScores.RecordFailure(chunk.Sender)
+		}
+		return false, fmt.Errorf("failed to decode chunk %v: %w", chunk.Index, err)
+	}
+	chunk.Chunk = decoded
+
 	added, err := s.chunks.Add(chunk)
 	if err != nil {
+		// This is where chunks.Add verifies the chunk's hash against the snapshot manifest, so an
+		// error here means the sender handed us corrupt data, not just a benign race with another
+		// sender's copy of the same chunk.
+		if chunk.Sender != "" {
+			s.peerScores.RecordFailure(chunk.Sender)
+		}
 		return false, err
 	}
 	if added {
 		s.logger.Debug("Added chunk to queue", "height", chunk.Height, "format", chunk.Format,
 			"chunk", chunk.Index)
+		s.auxMtx.Lock()
+		if s.manifest != nil && chunk.Index < s.manifest.Chunks {
+			s.manifest.Received[chunk.Index] = true
+			if err := s.chunkStore.Save(s.manifest); err != nil {
+				s.logger.Error("Failed to persist chunk manifest", "err", err)
+			}
+		}
+		if chunk.Sender != "" {
+			key := chunkRequestKey{index: chunk.Index, peer: chunk.Sender}
+			if sentAt, ok := s.chunkSentAt[key]; ok {
+				s.metrics.FetchChunkDuration.Observe(time.Since(sentAt).Seconds())
+				s.metrics.PeerChunksReceived.With("peer_id", string(chunk.Sender)).Add(1)
+				s.peerScores.RecordSuccess(chunk.Sender, time.Since(sentAt), len(chunk.Chunk))
+				delete(s.chunkSentAt, key)
+			}
+		}
+		s.auxMtx.Unlock()
+		s.metrics.ChunksReceived.Add(1)
 	} else {
 		s.logger.Debug("Ignoring duplicate chunk in queue", "height", chunk.Height, "format", chunk.Format,
 			"chunk", chunk.Index)
@@ -112,6 +235,11 @@ func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
 // AddSnapshot adds a snapshot to the snapshot pool. It returns true if a new, previously unseen
 // snapshot was accepted and added.
 func (s *syncer) AddSnapshot(peer p2p.Peer, snapshot *snapshot) (bool, error) {
+	select {
+	case <-s.ctx.Done():
+		return false, errSyncerStopped
+	default:
+	}
 	added, err := s.snapshots.Add(peer, snapshot)
 	if err != nil {
 		return false, err
@@ -119,6 +247,7 @@ func (s *syncer) AddSnapshot(peer p2p.Peer, snapshot *snapshot) (bool, error) {
 	if added {
 		s.logger.Info("Discovered new snapshot", "height", snapshot.Height, "format", snapshot.Format,
 			"hash", snapshot.Hash)
+		s.metrics.SnapshotsDiscovered.Add(1)
 	}
 	return added, nil
 }
@@ -130,6 +259,15 @@ func (s *syncer) AddPeer(peer p2p.Peer) {
 	peer.Send(SnapshotChannel, mustEncodeMsg(&ssproto.SnapshotsRequest{}))
 }
 
+// SetPeerCodecs records the chunk codecs a peer advertised support for, e.g. in the
+// supported_codecs field of its SnapshotsResponse. Peers that never advertise anything (older
+// nodes) are negotiated down to "none" by negotiateCodec.
+func (s *syncer) SetPeerCodecs(peer p2p.ID, codecs []string) {
+	s.auxMtx.Lock()
+	defer s.auxMtx.Unlock()
+	s.peerCodecs[peer] = codecs
+}
+
 // RemovePeer removes a peer from the pool.
 func (s *syncer) RemovePeer(peer p2p.Peer) {
 	s.logger.Debug("Removing peer from sync", "peer", peer.ID())
@@ -140,13 +278,17 @@ func (s *syncer) RemovePeer(peer p2p.Peer) {
 // snapshots if none were found and discoveryTime > 0. It returns the latest state and block commit
 // which the caller must use to bootstrap the node.
 func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.State, *types.Commit, error) {
+	syncStart := time.Now()
+	defer func() { s.metrics.SyncDuration.Observe(time.Since(syncStart).Seconds()) }()
 	if discoveryTime != 0 && discoveryTime < minimumDiscoveryTime {
 		discoveryTime = 5 * minimumDiscoveryTime
 	}
 
 	if discoveryTime > 0 {
 		s.logger.Info(fmt.Sprintf("Discovering snapshots for %v", discoveryTime))
-		time.Sleep(discoveryTime)
+		if err := s.sleep(discoveryTime); err != nil {
+			return sm.State{}, nil, err
+		}
 	}
 	s.logger.Info(fmt.Sprintf("Discover wait time pssed for %v", discoveryTime))
 
@@ -169,7 +311,9 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 			}
 			retryHook()
 			s.logger.Info(fmt.Sprintf("Discovering snapshots for %v", discoveryTime))
-			time.Sleep(discoveryTime)
+			if err := s.sleep(discoveryTime); err != nil {
+				return sm.State{}, nil, err
+			}
 			continue
 		}
 		if chunks == nil {
@@ -179,17 +323,28 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 			}
 			defer chunks.Close() // in case we forget to close it elsewhere
 		}
-		s.logger.Info(fmt.Sprintf("Start sync at %s", time.Now()))
 		newState, commit, err := s.Sync(snapshot, chunks)
-		s.logger.Info(fmt.Sprintf("Ended sync at %s", time.Now()))
 		switch {
 		case err == nil:
 			return newState, commit, nil
 
+		case errors.Is(err, errSyncerStopped):
+			return sm.State{}, nil, err
+
 		case errors.Is(err, errAbort):
+			if delErr := s.chunkStore.Delete(); delErr != nil {
+				s.logger.Error("Failed to remove chunk manifest", "err", delErr)
+			}
 			return sm.State{}, nil, err
 
 		case errors.Is(err, errRetrySnapshot):
+			// The persisted manifest must not survive a retry: resumeFromManifest would
+			// otherwise find it still matches this snapshot's height/format/hash/appHash on the
+			// next Sync() call and "resume" straight past every chunk already marked
+			// Applied/Received, silently no-opping the very retry the app asked for.
+			if delErr := s.chunkStore.Delete(); delErr != nil {
+				s.logger.Error("Failed to remove chunk manifest", "err", delErr)
+			}
 			chunks.RetryAll()
 			s.logger.Info("Retrying snapshot", "height", snapshot.Height, "format", snapshot.Format,
 				"hash", snapshot.Hash)
@@ -235,10 +390,23 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 	}
 }
 
+// sleep waits out d, returning errSyncerStopped early if the syncer's lifecycle context is
+// cancelled in the meantime instead of blocking a shutdown until the full duration elapses.
+func (s *syncer) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-s.ctx.Done():
+		return errSyncerStopped
+	}
+}
+
 // Sync executes a sync for a specific snapshot, returning the latest state and block commit which
 // the caller must use to bootstrap the node.
 func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.Commit, error) {
-	startTime := time.Now().UnixMilli()
+	verifyStart := time.Now()
 	s.mtx.Lock()
 	if s.chunks != nil {
 		s.mtx.Unlock()
@@ -252,7 +420,7 @@ func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.
 		s.mtx.Unlock()
 	}()
 
-	hctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	hctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
 	defer cancel()
 
 	appHash, err := s.stateProvider.AppHash(hctx, snapshot.Height)
@@ -264,32 +432,75 @@ func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.
 		return sm.State{}, nil, errRejectSnapshot
 	}
 	snapshot.trustedAppHash = appHash
+	verifyElapsed := time.Since(verifyStart)
 
-	getHashComplete := time.Now().UnixMilli()
-	getHashLatency := getHashComplete - startTime
-	s.logger.Info(fmt.Sprintf("GetHashLatency latency is: %d", getHashLatency))
+	s.metrics.SnapshotHeight.Set(float64(snapshot.Height))
+	s.metrics.SnapshotFormat.Set(float64(snapshot.Format))
+	s.metrics.ChunksTotal.Set(float64(snapshot.Chunks))
 
-	// Offer snapshot to ABCI app.
-	err = s.offerSnapshot(snapshot)
+	// If a manifest from a previous run of this same snapshot is still on disk, try to resume
+	// instead of re-offering and re-downloading everything.
+	manifest, resuming, err := s.resumeFromManifest(snapshot)
 	if err != nil {
-		return sm.State{}, nil, err
+		s.logger.Error("Failed to resume state sync from manifest, starting over", "err", err)
+		resuming = false
 	}
 
-	offerSnapshotComplete := time.Now().UnixMilli()
-	offerSnapshotLatency := offerSnapshotComplete - getHashComplete
-	s.logger.Info(fmt.Sprintf("OffserSnapShot latency is: %d", offerSnapshotLatency))
+	if resuming {
+		s.logger.Info("Resuming snapshot restoration from persisted manifest",
+			"height", snapshot.Height, "format", snapshot.Format,
+			"remaining", len(manifest.missingChunks()), "total", manifest.Chunks)
+		s.metrics.ChunksReceived.Set(float64(countTrue(manifest.Received)))
+		s.metrics.ChunksApplied.Set(float64(countTrue(manifest.Applied)))
+		s.metrics.ChunksRejected.Set(float64(countTrue(manifest.Rejected)))
+	} else {
+		s.metrics.ChunksReceived.Set(0)
+		s.metrics.ChunksApplied.Set(0)
+		s.metrics.ChunksRejected.Set(0)
+		// Offer snapshot to ABCI app.
+		offerStart := time.Now()
+		err = s.offerSnapshot(s.ctx, snapshot)
+		s.metrics.OfferSnapshotDuration.Observe(time.Since(offerStart).Seconds())
+		if err != nil {
+			return sm.State{}, nil, err
+		}
+		manifest = &chunkManifest{
+			Height:         snapshot.Height,
+			Format:         snapshot.Format,
+			Hash:           snapshot.Hash,
+			Chunks:         snapshot.Chunks,
+			TrustedAppHash: snapshot.trustedAppHash,
+			Received:       make([]bool, snapshot.Chunks),
+			Applied:        make([]bool, snapshot.Chunks),
+			Rejected:       make([]bool, snapshot.Chunks),
+		}
+		if err := s.chunkStore.Save(manifest); err != nil {
+			s.logger.Error("Failed to persist chunk manifest", "err", err)
+		}
+	}
 
-	// Spawn chunk fetchers. They will terminate when the chunk queue is closed or context cancelled.
-	fetchCtx, cancel := context.WithCancel(context.TODO())
+	s.mtx.Lock()
+	s.manifest = manifest
+	s.mtx.Unlock()
+	defer func() {
+		s.mtx.Lock()
+		s.manifest = nil
+		s.mtx.Unlock()
+	}()
+
+	// Spawn chunk fetchers. They will terminate when the chunk queue is closed or the syncer's
+	// lifecycle context is cancelled, e.g. by the reactor's OnStop.
+	fetchCtx, cancel := context.WithCancel(s.ctx)
 	defer cancel()
 	for i := int32(0); i < s.chunkFetchers; i++ {
 		go s.fetchChunks(fetchCtx, snapshot, chunks)
 	}
 
-	pctx, pcancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	pctx, pcancel := context.WithTimeout(s.ctx, 30*time.Second)
 	defer pcancel()
 
 	// Optimistically build new state, so we don't discover any light client failures at the end.
+	stateStart := time.Now()
 	state, err := s.stateProvider.State(pctx, snapshot.Height)
 	if err != nil {
 		s.logger.Info("failed to fetch and verify tendermint state", "err", err)
@@ -306,24 +517,26 @@ func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.
 		}
 		return sm.State{}, nil, errRejectSnapshot
 	}
-	buildEProviderStateComplete := time.Now().UnixMilli()
-	buildProviderStateLatency := buildEProviderStateComplete - offerSnapshotComplete
-	s.logger.Info(fmt.Sprintf("BuildProviderState latency is: %d", buildProviderStateLatency))
+	// verifyElapsed already holds the AppHash call's duration (measured in isolation, right after
+	// it returned); add just the State+Commit window here so the resume/offerSnapshot/fetcher-spawn
+	// work in between - already attributed to its own metrics - isn't double-counted into this one.
+	s.metrics.VerifyAppDuration.Observe((verifyElapsed + time.Since(stateStart)).Seconds())
 
 	// Restore snapshot
-	err = s.applyChunks(chunks)
+	err = s.applyChunks(s.ctx, chunks, snapshot, manifest)
 	if err != nil {
 		return sm.State{}, nil, err
 	}
-	applyChunksComplete := time.Now().UnixMilli()
-	applyChunksLatency := applyChunksComplete - buildEProviderStateComplete
-	s.logger.Info(fmt.Sprintf("ApplyChunks latency is: %d", applyChunksLatency))
 
 	// Verify app and app version
-	if err := s.verifyApp(snapshot, state.Version.Consensus.App); err != nil {
+	if err := s.verifyApp(s.ctx, snapshot, state.Version.Consensus.App); err != nil {
 		return sm.State{}, nil, err
 	}
 
+	if err := s.chunkStore.Delete(); err != nil {
+		s.logger.Error("Failed to remove chunk manifest", "err", err)
+	}
+
 	// Done! 🎉
 	s.logger.Info("Snapshot restored", "height", snapshot.Height, "format", snapshot.Format,
 		"hash", snapshot.Hash)
@@ -331,9 +544,43 @@ func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.
 	return state, commit, nil
 }
 
+// resumeFromManifest checks whether a manifest from a previous, interrupted restoration of this
+// exact snapshot (same trusted app hash) is still on disk. If so, it asks the app whether it
+// still considers that snapshot in progress via ListSnapshotsInProgress; if the app lists it, the
+// manifest is returned so Sync skips re-offering the snapshot and skips the chunks the manifest
+// marks as received or applied. If the app doesn't list it, or the query fails (e.g. the app
+// doesn't support it), no manifest is returned and Sync starts over.
+func (s *syncer) resumeFromManifest(snapshot *snapshot) (*chunkManifest, bool, error) {
+	manifest, err := s.chunkStore.Load()
+	if err != nil {
+		return nil, false, err
+	}
+	if manifest == nil || !manifest.forSnapshot(snapshot, snapshot.trustedAppHash) {
+		return nil, false, nil
+	}
+
+	resp, err := s.conn.ListSnapshotsInProgressSync(abci.RequestListSnapshotsInProgress{})
+	if err != nil {
+		// The app may simply not implement this ABCI extension; fall back to re-offering and
+		// treating a REJECT as "start over".
+		return nil, false, nil
+	}
+	for _, inProgress := range resp.Snapshots {
+		if inProgress.Height == uint64(snapshot.Height) && inProgress.Format == snapshot.Format {
+			return manifest, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
 // offerSnapshot offers a snapshot to the app. It returns various errors depending on the app's
 // response, or nil if the snapshot was accepted.
-func (s *syncer) offerSnapshot(snapshot *snapshot) error {
+func (s *syncer) offerSnapshot(ctx context.Context, snapshot *snapshot) error {
+	select {
+	case <-ctx.Done():
+		return errSyncerStopped
+	default:
+	}
 	s.logger.Info("Offering snapshot to ABCI app", "height", snapshot.Height,
 		"format", snapshot.Format, "hash", snapshot.Hash)
 	resp, err := s.conn.OfferSnapshotSync(abci.RequestOfferSnapshot{
@@ -367,95 +614,214 @@ func (s *syncer) offerSnapshot(snapshot *snapshot) error {
 	}
 }
 
-// applyChunks applies chunks to the app. It returns various errors depending on the app's
-// response, or nil once the snapshot is fully restored.
-func (s *syncer) applyChunks(chunks *chunkQueue) error {
-	var wg sync.WaitGroup
+// applyResult pairs an applied chunk with the app's response (or an error raising from the apply
+// call itself) so it can be routed back through applyChunks' result channel.
+type applyResult struct {
+	chunk *chunk
+	resp  abci.ResponseApplySnapshotChunk
+	err   error
+}
+
+// drainApplyResults feeds every result off resultCh to handle until resultCh is closed or ctx is
+// cancelled, returning the first error handle returns (if any). It exists as its own function,
+// separate from applyChunks, so the goroutine that consumes resultCh can be unit tested in
+// isolation: ranging over resultCh alone would never return if pctx were cancelled while a worker
+// was wedged inside an ABCI call, since nothing closes resultCh on that path.
+func drainApplyResults(ctx context.Context, resultCh <-chan applyResult, handle func(applyResult) error) error {
+	var err error
 	for {
-		s.logger.Info("Start applying chunks loop...")
+		select {
+		case result, ok := <-resultCh:
+			if !ok {
+				return err
+			}
+			if e := handle(result); e != nil && err == nil {
+				err = e
+			}
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// applyWorkerCount returns the size of applyChunks' worker pool: 1, so apps that require chunks
+// to be applied in order get that guarantee, unless the snapshot opts into out-of-order
+// application via ParallelApply, in which case it's the configured chunkApplyWorkers.
+func applyWorkerCount(snapshot *snapshot, configured int32) int32 {
+	if snapshot.ParallelApply {
+		return configured
+	}
+	return 1
+}
+
+// applyChunks applies chunks to the app using a bounded pool of workers. The pool size is 1 by
+// default so apps that require chunks to be applied in order get that guarantee, and grows to
+// s.chunkApplyWorkers for snapshots that opt into out-of-order application via
+// snapshot.ParallelApply. It returns various errors depending on the app's response, or nil once
+// the snapshot is fully restored. pctx is the parent context (ultimately the syncer's lifecycle
+// context); once it is cancelled - e.g. via the reactor's OnStop - no further chunks are handed to
+// the worker pool and applyChunks returns pctx.Err() without waiting for ApplySnapshotChunk calls
+// already in flight.
+func (s *syncer) applyChunks(pctx context.Context, chunks *chunkQueue, snapshot *snapshot, manifest *chunkManifest) error {
+	workers := applyWorkerCount(snapshot, s.chunkApplyWorkers)
+
+	ctx, cancel := context.WithCancel(pctx)
+	defer cancel()
+
+	sem := make(chan struct{}, workers)
+	resultCh := make(chan applyResult, workers)
+
+	var applyWg sync.WaitGroup
+	var resultErr error
+	resultDone := make(chan struct{})
+	go func() {
+		defer close(resultDone)
+		resultErr = drainApplyResults(ctx, resultCh, func(result applyResult) error {
+			return s.handleApplyResult(chunks, manifest, result)
+		})
+		if resultErr != nil {
+			cancel()
+		}
+	}()
+
+	var feedErr error
+feed:
+	for {
+		select {
+		case <-ctx.Done():
+			break feed
+		default:
+		}
 
-		waitForNextChunkStart := time.Now().UnixMilli()
 		chunk, err := chunks.Next()
 		if err == errDone {
 			break
 		} else if err != nil {
-			return fmt.Errorf("failed to fetch chunk: %w", err)
+			feedErr = fmt.Errorf("failed to fetch chunk: %w", err)
+			cancel()
+			break
 		}
 
-		waitForNextChunkEnd := time.Now().UnixMilli()
-		waitForNextChunkLatency := waitForNextChunkEnd - waitForNextChunkStart
-		s.logger.Info(fmt.Sprintf("Wait for next chunk id %d latency is: %d", chunk.Index, waitForNextChunkLatency))
+		// A manifest resumed from a previous run already recorded this index as applied; don't
+		// replay it to the ABCI app, which may reject a chunk it already committed. Applied is
+		// written under auxMtx by handleApplyResult, which runs concurrently in the result
+		// consumer goroutine started above, so it must be read under the same lock - same
+		// reasoning as isChunkReceived's read of manifest.Received.
+		s.auxMtx.Lock()
+		alreadyApplied := chunk.Index < manifest.Chunks && manifest.Applied[chunk.Index]
+		s.auxMtx.Unlock()
+		if alreadyApplied {
+			s.logger.Debug("Skipping chunk already applied per resumed manifest", "chunk", chunk.Index)
+			continue
+		}
 
-		s.logger.Info(fmt.Sprintf("Starting to apply chunk async for chunk id %d", chunk.Index))
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			req := abci.RequestApplySnapshotChunk{
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break feed
+		}
+
+		applyWg.Add(1)
+		go func(chunk *chunk) {
+			defer applyWg.Done()
+			defer func() { <-sem }()
+			applyStart := time.Now()
+			resp, err := s.conn.ApplySnapshotChunkSync(abci.RequestApplySnapshotChunk{
 				Index:  chunk.Index,
 				Chunk:  chunk.Chunk,
 				Sender: string(chunk.Sender),
+			})
+			s.metrics.ApplyChunkDuration.Observe(time.Since(applyStart).Seconds())
+			select {
+			case resultCh <- applyResult{chunk: chunk, resp: resp, err: err}:
+			case <-ctx.Done():
 			}
-			s.conn.ApplySnapshotChunkSync(req)
-		}()
+		}(chunk)
+	}
+
+	applyWgDone := make(chan struct{})
+	go func() {
+		applyWg.Wait()
+		close(applyWgDone)
+	}()
+
+	// Don't block shutdown on straggling workers: if pctx is cancelled (e.g. the reactor's
+	// OnStop) while an ApplySnapshotChunkSync call is wedged against an unresponsive app, return
+	// immediately rather than waiting on applyWg, which has no way to interrupt a call already in
+	// flight. The leaked workers are still bounded by sem and will exit once their RPC returns.
+	select {
+	case <-applyWgDone:
+	case <-pctx.Done():
+		return pctx.Err()
+	}
+	close(resultCh)
+	<-resultDone
+
+	if feedErr != nil {
+		return feedErr
+	}
+	return resultErr
+}
 
-		//applySnapshotChunkEnd := time.Now().UnixMilli()
-		//applySnapshotChunkLatency := applySnapshotChunkEnd - waitForNextChunkEnd
-		//s.logger.Info(fmt.Sprintf("Apply chunk id %d latency is: %d", chunk.Index, applySnapshotChunkLatency))
-		//
-		//if err != nil {
-		//	return fmt.Errorf("failed to apply chunk %v: %w", chunk.Index, err)
-		//}
-		//s.logger.Info("Applied snapshot chunk to ABCI app", "height", chunk.Height,
-		//	"format", chunk.Format, "chunk", chunk.Index, "total", chunks.Size())
-		//
-		//// Discard and refetch any chunks as requested by the app
-		//for _, index := range resp.RefetchChunks {
-		//	err := chunks.Discard(index)
-		//	if err != nil {
-		//		return fmt.Errorf("failed to discard chunk %v: %w", index, err)
-		//	}
-		//}
-		//
-		//// Reject any senders as requested by the app
-		//for _, sender := range resp.RejectSenders {
-		//	if sender != "" {
-		//		s.snapshots.RejectPeer(p2p.ID(sender))
-		//		err := chunks.DiscardSender(p2p.ID(sender))
-		//		if err != nil {
-		//			return fmt.Errorf("failed to reject sender: %w", err)
-		//		}
-		//	}
-		//}
-		//
-		//switch resp.Result {
-		//case abci.ResponseApplySnapshotChunk_ACCEPT:
-		//case abci.ResponseApplySnapshotChunk_ABORT:
-		//	return errAbort
-		//case abci.ResponseApplySnapshotChunk_RETRY:
-		//	chunks.Retry(chunk.Index)
-		//case abci.ResponseApplySnapshotChunk_RETRY_SNAPSHOT:
-		//	return errRetrySnapshot
-		//case abci.ResponseApplySnapshotChunk_REJECT_SNAPSHOT:
-		//	return errRejectSnapshot
-		//default:
-		//	return fmt.Errorf("unknown ResponseApplySnapshotChunk result %v", resp.Result)
-		//}
-	}
-	s.logger.Info(fmt.Sprintf("Now starting to wait for all applying chunks to complete"))
-	wg.Wait()
-	s.logger.Info(fmt.Sprintf("Everything Done"))
+// handleApplyResult processes a single ResponseApplySnapshotChunk, discarding/refetching chunks
+// and rejecting senders as requested by the app, and persists the updated manifest so a crash
+// right after this point can resume from here rather than from scratch.
+func (s *syncer) handleApplyResult(chunks *chunkQueue, manifest *chunkManifest, result applyResult) error {
+	if result.err != nil {
+		return fmt.Errorf("failed to apply chunk %v: %w", result.chunk.Index, result.err)
+	}
+	resp := result.resp
+	s.logger.Info("Applied snapshot chunk to ABCI app", "height", result.chunk.Height,
+		"format", result.chunk.Format, "chunk", result.chunk.Index, "total", chunks.Size())
+
+	s.auxMtx.Lock()
+	defer s.auxMtx.Unlock()
+
+	// Discard and refetch any chunks as requested by the app
+	for _, index := range resp.RefetchChunks {
+		if err := chunks.Discard(index); err != nil {
+			return fmt.Errorf("failed to discard chunk %v: %w", index, err)
+		}
+		manifest.markForRefetch(index)
+		s.metrics.ChunksRejected.Add(1)
+	}
+
+	// Reject any senders as requested by the app
+	for _, sender := range resp.RejectSenders {
+		if sender != "" {
+			s.snapshots.RejectPeer(p2p.ID(sender))
+			if err := chunks.DiscardSender(p2p.ID(sender)); err != nil {
+				return fmt.Errorf("failed to reject sender: %w", err)
+			}
+		}
+	}
+
+	switch resp.Result {
+	case abci.ResponseApplySnapshotChunk_ACCEPT:
+		manifest.Applied[result.chunk.Index] = true
+		s.metrics.ChunksApplied.Add(1)
+	case abci.ResponseApplySnapshotChunk_ABORT:
+		return errAbort
+	case abci.ResponseApplySnapshotChunk_RETRY:
+		chunks.Retry(result.chunk.Index)
+	case abci.ResponseApplySnapshotChunk_RETRY_SNAPSHOT:
+		return errRetrySnapshot
+	case abci.ResponseApplySnapshotChunk_REJECT_SNAPSHOT:
+		manifest.Rejected[result.chunk.Index] = true
+		return errRejectSnapshot
+	default:
+		return fmt.Errorf("unknown ResponseApplySnapshotChunk result %v", resp.Result)
+	}
+
+	if err := s.chunkStore.Save(manifest); err != nil {
+		s.logger.Error("Failed to persist chunk manifest", "err", err)
+	}
 	return nil
 }
 
 // fetchChunks requests chunks from peers, receiving allocations from the chunk queue. Chunks
 // will be received from the reactor via syncer.AddChunks() to chunkQueue.Add().
 func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *chunkQueue) {
-	startTime := time.Now().UnixMilli()
-	defer func() {
-		endTime := time.Now().UnixMilli()
-		latency := endTime - startTime
-		s.logger.Info(fmt.Sprintf("FetchChunks latency is %d", latency))
-	}()
 	var (
 		next  = true
 		index uint32
@@ -484,55 +850,215 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 		s.logger.Info("Fetching snapshot chunk", "height", snapshot.Height,
 			"format", snapshot.Format, "chunk", index, "total", chunks.Size())
 
-		ticker := time.NewTicker(s.retryTimeout)
-		defer ticker.Stop()
-
-		requestStart := time.Now().UnixMilli()
-		s.requestChunk(snapshot, index)
+		received, err := s.fetchChunk(ctx, snapshot, chunks, index)
+		if err != nil {
+			return
+		}
+		next = received
+	}
+}
 
+// fetchChunk requests a single chunk from the top-ranked peers for snapshot (at most
+// parallelRequests of them), sending the request to all of them at once, each with its own
+// adaptive timeout; it does not wait for one peer's timeout before contacting the others. It
+// returns once the chunk has been received (received=true) or every selected peer has timed out
+// (received=false, so the caller re-allocates before trying again). It only returns an error
+// when ctx is done, since that's the one case the caller should stop fetching altogether.
+func (s *syncer) fetchChunk(ctx context.Context, snapshot *snapshot, chunks *chunkQueue, index uint32) (bool, error) {
+	if s.isChunkReceived(index) {
+		// Resumed from a manifest that already recorded this index as received: the chunk file is
+		// still on disk in tempDir and newChunkQueue picked it up on construction, so there's
+		// nothing to fetch over the network - just wait for the queue to hand it to applyChunks.
+		s.logger.Debug("Skipping fetch of chunk already received per resumed manifest", "chunk", index)
 		select {
 		case <-chunks.WaitFor(index):
-			next = true
-			requestEnd := time.Now().UnixMilli()
-			latency := requestEnd - requestStart
-			s.logger.Info(fmt.Sprintf("RequestChunk wait for id %d latency is %d", index, latency))
+			return true, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	peers := s.getPeersRanked(snapshot)
+	if len(peers) == 0 {
+		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
+			"format", snapshot.Format, "hash", snapshot.Hash)
+		select {
+		case <-time.After(s.retryTimeout):
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	peers = peers[:parallelFetchCount(len(peers), s.parallelRequests)]
+
+	waitCh := chunks.WaitFor(index)
 
-		case <-ticker.C:
-			next = false
-			requestEnd := time.Now().UnixMilli()
-			latency := requestEnd - requestStart
-			s.logger.Info(fmt.Sprintf("RequestChunk ticker id %d latency is %d", index, latency))
+	// Issue the request to every selected peer up front rather than waiting out one peer's
+	// adaptive timeout before trying the next: a single slow or dishonest peer shouldn't stall the
+	// chunk when we already know who else claims to have it. Each peer gets its own timer, sized
+	// to its own adaptive timeout, and whichever reply arrives first wins; AddChunk's existing
+	// added=false handling takes care of ignoring the losers' later duplicates.
+	timedOut := make(chan p2p.Peer, len(peers))
+	for _, peer := range peers {
+		peer := peer
+		s.requestChunkFrom(snapshot, index, peer)
+		timeout := s.peerScores.AdaptiveTimeout(peer.ID(), s.timeoutStdDevs, s.retryTimeout/4, s.retryTimeout*4)
+		go func() {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			select {
+			case <-waitCh:
+			case <-ctx.Done():
+			case <-timer.C:
+				timedOut <- peer
+			}
+		}()
+	}
+
+	remaining := len(peers)
+	for {
+		select {
+		case <-waitCh:
+			return true, nil
+
+		case peer := <-timedOut:
+			s.peerScores.RecordFailure(peer.ID())
+			s.metrics.PeerChunkFailures.With("peer_id", string(peer.ID())).Add(1)
+			remaining--
+			if remaining == 0 {
+				return false, nil
+			}
 
 		case <-ctx.Done():
-			requestEnd := time.Now().UnixMilli()
-			latency := requestEnd - requestStart
-			s.logger.Info(fmt.Sprintf("RequestChunk done id %d latency is %d", index, latency))
-			return
+			return false, ctx.Err()
 		}
+	}
+}
 
-		ticker.Stop()
+// parallelFetchCount returns how many of the available peers fetchChunk should fan a request out
+// to: all of them, capped at parallelRequests, so a single slow or dishonest peer can't stall a
+// chunk when other peers already claim to have it, without flooding every peer on every chunk.
+func parallelFetchCount(available int, parallelRequests int32) int {
+	if int32(available) > parallelRequests {
+		return int(parallelRequests)
 	}
+	return available
 }
 
-// requestChunk requests a chunk from a peer.
-func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
-	peer := s.snapshots.GetPeer(snapshot)
-	if peer == nil {
-		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
-			"format", snapshot.Format, "hash", snapshot.Hash)
-		return
+// getPeersRanked returns the peers known to have snapshot, sorted by descending peer score (see
+// peerScoreTracker), so fetchChunk can prefer faster and more reliable peers.
+func (s *syncer) getPeersRanked(snapshot *snapshot) []p2p.Peer {
+	return s.peerScores.Ranked(s.snapshots.GetPeers(snapshot))
+}
+
+// isChunkReceived reports whether index was already recorded as received in the manifest we
+// resumed from, i.e. whether fetchChunk can skip re-requesting it over the network. Received is
+// also written by AddChunk and handleApplyResult under auxMtx, so it must be read under the same
+// lock rather than just mtx, which only protects the s.manifest pointer itself.
+func (s *syncer) isChunkReceived(index uint32) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if s.manifest == nil {
+		return false
 	}
+	s.auxMtx.Lock()
+	defer s.auxMtx.Unlock()
+	return index < s.manifest.Chunks && s.manifest.Received[index]
+}
+
+// requestChunkFrom requests a chunk from a specific peer, recording the request time (keyed by
+// peer as well as index, since fetchChunk may have fanned this same index out to other peers too)
+// so AddChunk can compute that peer's own round-trip time once (if) it replies.
+func (s *syncer) requestChunkFrom(snapshot *snapshot, chunk uint32, peer p2p.Peer) {
+	s.auxMtx.Lock()
+	s.chunkSentAt[chunkRequestKey{index: chunk, peer: peer.ID()}] = time.Now()
+	s.auxMtx.Unlock()
+
+	// We send our preferred codecs, filtered down to what this peer is known to support (see
+	// codecsForPeer), rather than negotiating locally - it's the sender that knows which codec it
+	// actually supports encoding chunks with; see negotiateCodec, which the reactor's chunk-request
+	// handler uses on the other end to pick one from this list.
+	codecs := s.codecsForPeer(peer.ID())
 	s.logger.Debug("Requesting snapshot chunk", "height", snapshot.Height,
-		"format", snapshot.Format, "chunk", chunk, "peer", peer.ID())
+		"format", snapshot.Format, "chunk", chunk, "peer", peer.ID(), "codecs", codecs)
 	peer.Send(ChunkChannel, mustEncodeMsg(&ssproto.ChunkRequest{
 		Height: snapshot.Height,
 		Format: snapshot.Format,
 		Index:  chunk,
+		Codecs: codecs,
 	}))
 }
 
+// codecsForPeer returns the codec list to advertise in a ChunkRequest sent to peer: our full
+// preference list, unless peer advertised its own supported_codecs (via SetPeerCodecs, recorded
+// from its SnapshotsResponse), in which case we narrow down to the overlap so we don't bother
+// advertising codecs we already know the peer can't use.
+func (s *syncer) codecsForPeer(peer p2p.ID) []string {
+	s.auxMtx.Lock()
+	supported, ok := s.peerCodecs[peer]
+	s.auxMtx.Unlock()
+	if !ok || len(supported) == 0 {
+		return s.preferredCodecs
+	}
+	set := make(map[string]bool, len(supported))
+	for _, c := range supported {
+		set[c] = true
+	}
+	filtered := make([]string, 0, len(s.preferredCodecs))
+	for _, c := range s.preferredCodecs {
+		if set[c] {
+			filtered = append(filtered, c)
+		}
+	}
+	if len(filtered) == 0 {
+		return []string{"none"}
+	}
+	return filtered
+}
+
+// EncodeChunkForResponse picks a codec to use when replying to a ChunkRequest and encodes data
+// with it, returning the encoded bytes and the codec name to tag the ChunkResponse with. It's the
+// sender-side half of the codec feature: the reactor's chunk-request handler calls this (after
+// loading the raw chunk via the ABCI app) before putting a ChunkResponse on the wire, negotiating
+// down to "none" if requesterCodecs is empty (an old peer) or shares nothing with what we support.
+// It's a plain function, not a syncer method, since it only touches the package-level codec
+// registry - a node serves chunks to peers regardless of whether it's also restoring one itself.
+func EncodeChunkForResponse(requesterCodecs []string, data []byte) (encoded []byte, codecName string, err error) {
+	ourCodecs := make([]string, 0, len(registeredCodecs))
+	for name := range registeredCodecs {
+		ourCodecs = append(ourCodecs, name)
+	}
+	codecName = negotiateCodec(requesterCodecs, ourCodecs)
+	codec, ok := codecByName(codecName)
+	if !ok {
+		codec, codecName = registeredCodecs["none"], "none"
+	}
+	encoded, err = codec.Encode(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to %s-encode chunk: %w", codecName, err)
+	}
+	return encoded, codecName, nil
+}
+
+// requestChunk requests a chunk from a single peer, chosen via snapshots.GetPeer. It's retained
+// for callers that don't need multi-peer fan-out.
+func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
+	peer := s.snapshots.GetPeer(snapshot)
+	if peer == nil {
+		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
+			"format", snapshot.Format, "hash", snapshot.Hash)
+		return
+	}
+	s.requestChunkFrom(snapshot, chunk, peer)
+}
+
 // verifyApp verifies the sync, checking the app hash, last block height and app version
-func (s *syncer) verifyApp(snapshot *snapshot, appVersion uint64) error {
+func (s *syncer) verifyApp(ctx context.Context, snapshot *snapshot, appVersion uint64) error {
+	select {
+	case <-ctx.Done():
+		return errSyncerStopped
+	default:
+	}
 	resp, err := s.connQuery.InfoSync(proxy.RequestInfo)
 	if err != nil {
 		return fmt.Errorf("failed to query ABCI app for appHash: %w", err)
@@ -0,0 +1,129 @@
+package statesync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/p2p"
+)
+
+func TestDrainApplyResultsReturnsOnClose(t *testing.T) {
+	resultCh := make(chan applyResult, 3)
+	resultCh <- applyResult{}
+	resultCh <- applyResult{}
+	close(resultCh)
+
+	handled := 0
+	err := drainApplyResults(context.Background(), resultCh, func(applyResult) error {
+		handled++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, handled)
+}
+
+func TestDrainApplyResultsReturnsFirstHandleError(t *testing.T) {
+	resultCh := make(chan applyResult, 2)
+	resultCh <- applyResult{}
+	resultCh <- applyResult{}
+	close(resultCh)
+
+	boom := errors.New("boom")
+	handled := 0
+	err := drainApplyResults(context.Background(), resultCh, func(applyResult) error {
+		handled++
+		return boom
+	})
+	require.Equal(t, boom, err)
+	require.Equal(t, 2, handled, "draining continues after the first error, same as applyChunks expects")
+}
+
+// TestDrainApplyResultsReturnsOnCancel pins down the goroutine-leak bug this function was
+// extracted to fix: if resultCh is never closed (e.g. a worker is wedged inside an ABCI call when
+// the parent context is cancelled), drainApplyResults must still return promptly rather than
+// blocking forever on a channel that will never produce or close.
+func TestDrainApplyResultsReturnsOnCancel(t *testing.T) {
+	resultCh := make(chan applyResult) // never sent to, never closed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- drainApplyResults(ctx, resultCh, func(applyResult) error { return nil })
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("drainApplyResults leaked: did not return after ctx was cancelled")
+	}
+}
+
+func TestIsChunkReceived(t *testing.T) {
+	s := &syncer{}
+	require.False(t, s.isChunkReceived(0), "no manifest yet")
+
+	s.manifest = &chunkManifest{Chunks: 2, Received: []bool{true, false}}
+	require.True(t, s.isChunkReceived(0))
+	require.False(t, s.isChunkReceived(1))
+	require.False(t, s.isChunkReceived(5), "out-of-range index")
+}
+
+// TestIsChunkReceivedRace pins down the isChunkReceived/AddChunk data race: Received is written
+// under auxMtx elsewhere (AddChunk, handleApplyResult), so isChunkReceived must take the same
+// lock rather than relying on mtx, which only protects the s.manifest pointer itself. Run with
+// -race to catch a regression.
+func TestIsChunkReceivedRace(t *testing.T) {
+	s := &syncer{manifest: &chunkManifest{Chunks: 1, Received: []bool{false}}}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			s.isChunkReceived(0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			s.auxMtx.Lock()
+			s.manifest.Received[0] = !s.manifest.Received[0]
+			s.auxMtx.Unlock()
+		}
+	}()
+	wg.Wait()
+}
+
+func TestApplyWorkerCount(t *testing.T) {
+	require.EqualValues(t, 1, applyWorkerCount(&snapshot{ParallelApply: false}, 4),
+		"apps that require in-order application get a single worker regardless of config")
+	require.EqualValues(t, 4, applyWorkerCount(&snapshot{ParallelApply: true}, 4))
+}
+
+func TestParallelFetchCount(t *testing.T) {
+	require.Equal(t, 2, parallelFetchCount(5, 2), "fans out to at most parallelRequests peers")
+	require.Equal(t, 3, parallelFetchCount(3, 5), "never fans out to more peers than are available")
+}
+
+func TestCodecsForPeer(t *testing.T) {
+	s := &syncer{
+		preferredCodecs: []string{"zstd", "gzip", "none"},
+		peerCodecs:      make(map[p2p.ID][]string),
+	}
+
+	require.Equal(t, []string{"zstd", "gzip", "none"}, s.codecsForPeer("unknown"),
+		"no advertised codecs falls back to our full preference list")
+
+	s.peerCodecs["p1"] = []string{"gzip", "none"}
+	require.Equal(t, []string{"gzip", "none"}, s.codecsForPeer("p1"), "narrowed to the overlap")
+
+	s.peerCodecs["p2"] = []string{"lz4"}
+	require.Equal(t, []string{"none"}, s.codecsForPeer("p2"), "no overlap falls back to none")
+}